@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FaultConfig holds the set of fault-injection rules currently active on
+// the server. Rules can be seeded from the JSON config file and replaced
+// at runtime via POST /admin/faults.
+type FaultConfig struct {
+	Rules []FaultRule `json:"rules"`
+}
+
+// FaultRule describes a single fault to inject. A rule matches a request
+// by Path and Model (either left empty to match anything) and, when it
+// fires with probability Probability, injects Type's failure mode.
+type FaultRule struct {
+	Path         string  `json:"path,omitempty"`
+	Model        string  `json:"model,omitempty"`
+	Probability  float64 `json:"probability"`
+	Type         string  `json:"type"`
+	HTTPStatus   int     `json:"http_status,omitempty"`
+	TimeoutAfter string  `json:"timeout_after,omitempty"`
+}
+
+const (
+	faultHTTPStatus    = "http_status"
+	faultTimeout       = "timeout"
+	faultPartialStream = "partial_stream"
+	faultMalformedJSON = "malformed_json"
+)
+
+// faultPartialStreamKey is the gin context key streamChatCompletion checks
+// to decide whether to cut a stream short instead of completing normally.
+const faultPartialStreamKey = "fault_partial_stream"
+
+// faultInjectionMiddleware matches the incoming request against the
+// configured fault rules and, on a hit, injects the configured failure
+// mode instead of (or partway through) letting the request proceed
+// normally.
+func faultInjectionMiddleware(config *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Never inject faults into the admin API itself - a wildcard or
+		// /admin-targeted rule would otherwise be able to lock out the very
+		// endpoint ("/admin/faults") needed to clear it.
+		if strings.HasPrefix(c.Request.URL.Path, "/admin") {
+			c.Next()
+			return
+		}
+
+		rule, ok := matchFaultRule(config, c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		switch rule.Type {
+		case faultHTTPStatus:
+			injectHTTPStatusFault(c, rule)
+		case faultTimeout:
+			injectTimeoutFault(c, rule)
+		case faultMalformedJSON:
+			injectMalformedJSONFault(c)
+		case faultPartialStream:
+			c.Set(faultPartialStreamKey, true)
+			c.Next()
+		default:
+			log.Printf("Warning: Unknown fault type %q. Ignoring rule.", rule.Type)
+			c.Next()
+		}
+	}
+}
+
+// matchFaultRule finds the first configured rule whose path/model match
+// the request and whose probability roll succeeds.
+func matchFaultRule(config *Config, c *gin.Context) (FaultRule, bool) {
+	config.faultsMu.RLock()
+	rules := config.Faults.Rules
+	config.faultsMu.RUnlock()
+
+	if len(rules) == 0 {
+		return FaultRule{}, false
+	}
+
+	model := peekRequestModel(c)
+
+	for _, rule := range rules {
+		if rule.Path != "" && rule.Path != c.Request.URL.Path {
+			continue
+		}
+		if rule.Model != "" && rule.Model != model {
+			continue
+		}
+		if rand.Float64() >= rule.Probability {
+			continue
+		}
+		return rule, true
+	}
+
+	return FaultRule{}, false
+}
+
+// injectHTTPStatusFault aborts the request with an OpenAI-shaped error
+// envelope for the rule's configured status code.
+func injectHTTPStatusFault(c *gin.Context, rule FaultRule) {
+	status := rule.HTTPStatus
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	errType := "server_error"
+	switch status {
+	case http.StatusTooManyRequests:
+		errType = "rate_limit_exceeded"
+	case http.StatusServiceUnavailable:
+		errType = "server_error"
+	}
+
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		c.Header("Retry-After", strconv.Itoa(1))
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": map[string]interface{}{
+			"message": fmt.Sprintf("Injected fault: simulated %d response", status),
+			"type":    errType,
+			"code":    errType,
+		},
+	})
+}
+
+// injectTimeoutFault sleeps past the rule's configured deadline and then
+// closes the connection without writing a response, simulating a server
+// that hung and never replied.
+func injectTimeoutFault(c *gin.Context, rule FaultRule) {
+	delay := 60 * time.Second
+	if rule.TimeoutAfter != "" {
+		if parsed, err := time.ParseDuration(rule.TimeoutAfter); err == nil {
+			delay = parsed
+		} else {
+			log.Printf("Warning: invalid timeout_after %q in fault rule: %v", rule.TimeoutAfter, err)
+		}
+	}
+
+	time.Sleep(delay)
+
+	hijacker, ok := c.Writer.(http.Hijacker)
+	if !ok {
+		c.AbortWithStatus(http.StatusGatewayTimeout)
+		return
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		c.AbortWithStatus(http.StatusGatewayTimeout)
+		return
+	}
+	conn.Close()
+	c.Abort()
+}
+
+// injectMalformedJSONFault wraps the response writer so only a handful of
+// bytes of whatever the handler writes make it to the client, truncating
+// the JSON body mid-stream.
+func injectMalformedJSONFault(c *gin.Context) {
+	c.Writer = &truncatingWriter{ResponseWriter: c.Writer, remaining: 32}
+	c.Next()
+}
+
+// truncatingWriter passes through at most `remaining` bytes to the
+// underlying ResponseWriter and silently drops the rest, while still
+// reporting a full write to the caller so handlers don't error out.
+type truncatingWriter struct {
+	gin.ResponseWriter
+	remaining int
+}
+
+func (w *truncatingWriter) Write(data []byte) (int, error) {
+	if w.remaining <= 0 {
+		return len(data), nil
+	}
+	if len(data) <= w.remaining {
+		n, err := w.ResponseWriter.Write(data)
+		w.remaining -= n
+		return len(data), err
+	}
+
+	n, err := w.ResponseWriter.Write(data[:w.remaining])
+	w.remaining -= n
+	if err != nil {
+		return n, err
+	}
+	return len(data), nil
+}
+
+// handleSetFaults replaces the server's active fault rules at runtime,
+// letting clients hot-reload fault injection without restarting the
+// process.
+func handleSetFaults(c *gin.Context, config *Config) {
+	var faults FaultConfig
+	if err := c.ShouldBindJSON(&faults); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"message": fmt.Sprintf("Invalid fault configuration: %v", err),
+				"type":    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	config.faultsMu.Lock()
+	config.Faults = faults
+	config.faultsMu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"rules": faults.Rules})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestContext(method, path, body string) *gin.Context {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(method, path, strings.NewReader(body))
+	return c
+}
+
+func TestMatchFaultRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []FaultRule
+		path      string
+		body      string
+		wantMatch bool
+	}{
+		{
+			name:      "no rules configured",
+			path:      "/v1/chat/completions",
+			wantMatch: false,
+		},
+		{
+			name:      "path mismatch",
+			rules:     []FaultRule{{Path: "/v1/embeddings", Probability: 1, Type: faultHTTPStatus}},
+			path:      "/v1/chat/completions",
+			wantMatch: false,
+		},
+		{
+			name:      "wildcard path and model matches",
+			rules:     []FaultRule{{Probability: 1, Type: faultHTTPStatus}},
+			path:      "/v1/chat/completions",
+			wantMatch: true,
+		},
+		{
+			name:      "model mismatch",
+			rules:     []FaultRule{{Model: "gpt-4", Probability: 1, Type: faultHTTPStatus}},
+			path:      "/v1/chat/completions",
+			body:      `{"model":"gpt-3.5-turbo"}`,
+			wantMatch: false,
+		},
+		{
+			name:      "zero probability never fires",
+			rules:     []FaultRule{{Probability: 0, Type: faultHTTPStatus}},
+			path:      "/v1/chat/completions",
+			wantMatch: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Faults: FaultConfig{Rules: tt.rules}}
+			c := newTestContext(http.MethodPost, tt.path, tt.body)
+
+			_, ok := matchFaultRule(config, c)
+			if ok != tt.wantMatch {
+				t.Errorf("matchFaultRule() match = %v, want %v", ok, tt.wantMatch)
+			}
+		})
+	}
+}
+
+// TestFaultInjectionMiddlewareExemptsAdmin guards against a regression
+// where a wildcard fault rule could lock out /admin/faults itself, leaving
+// no way to clear the rule short of restarting the process.
+func TestFaultInjectionMiddlewareExemptsAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &Config{Faults: FaultConfig{Rules: []FaultRule{{Probability: 1, Type: faultHTTPStatus, HTTPStatus: http.StatusInternalServerError}}}}
+
+	router := gin.New()
+	router.Use(faultInjectionMiddleware(config))
+	router.POST("/admin/faults", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"rules": []FaultRule{}})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/faults", strings.NewReader(`{"rules":[]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (admin routes must bypass fault injection)", rec.Code, http.StatusOK)
+	}
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"math"
 	"math/rand"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +24,8 @@ var (
 	minLatency  time.Duration
 	maxLatency  time.Duration
 	configFile  string
+	recordDir   string
+	replayDir   string
 	defaultPort = 8080
 )
 
@@ -27,10 +34,16 @@ func init() {
 	flag.DurationVar(&minLatency, "min-latency", 0, "Minimum latency to simulate (e.g., 100ms)")
 	flag.DurationVar(&maxLatency, "max-latency", 0, "Maximum latency to simulate (e.g., 500ms)")
 	flag.StringVar(&configFile, "config", "", "Path to configuration file")
-	flag.Parse()
+	flag.StringVar(&recordDir, "record", "", "Directory to record request/response fixtures to")
+	flag.StringVar(&replayDir, "replay", "", "Directory to replay request/response fixtures from")
 }
 
 func main() {
+	// Parsing here rather than in init() keeps `go test` usable: flag.Parse
+	// in init() would otherwise choke on go test's own flags before any
+	// test gets to run.
+	flag.Parse()
+
 	// Initialize configuration
 	config, err := loadConfig(configFile)
 	if err != nil {
@@ -56,6 +69,27 @@ func main() {
 	if maxLatency != 0 {
 		config.MaxLatency = maxLatency
 	}
+	if recordDir != "" {
+		config.RecordDir = recordDir
+	}
+	if replayDir != "" {
+		config.ReplayDir = replayDir
+	}
+
+	if config.RecordDir != "" {
+		if err := os.MkdirAll(config.RecordDir, 0o755); err != nil {
+			log.Fatalf("Failed to create record directory %q: %v", config.RecordDir, err)
+		}
+		log.Printf("Recording request/response fixtures to %s", config.RecordDir)
+	}
+	if config.ReplayDir != "" {
+		fixtures, err := loadFixtures(config.ReplayDir)
+		if err != nil {
+			log.Fatalf("Failed to load fixtures from %q: %v", config.ReplayDir, err)
+		}
+		config.fixtures = fixtures
+		log.Printf("Replaying %d fixture(s) from %s", len(fixtures), config.ReplayDir)
+	}
 
 	// Set up the router
 	router := setupRouter(config)
@@ -72,8 +106,31 @@ func main() {
 func setupRouter(config *Config) *gin.Engine {
 	router := gin.Default()
 
+	// Recording wraps the response writer outermost so it captures what
+	// actually reaches the client - including fault injection's truncated
+	// or error bodies - rather than what the handler originally wrote.
+	router.Use(recordingMiddleware(config))
+
+	// Fault injection runs before the latency middleware so injected
+	// errors/timeouts don't pay the simulated latency on top of themselves.
+	router.Use(faultInjectionMiddleware(config))
+
 	// Add middleware for simulating latency
-	router.Use(simulateLatencyMiddleware(config.MinLatency, config.MaxLatency))
+	router.Use(simulateLatencyMiddleware(config))
+
+	// Admin routes for runtime control of the mock server
+	adminGroup := router.Group("/admin")
+	{
+		adminGroup.POST("/faults", func(c *gin.Context) {
+			handleSetFaults(c, config)
+		})
+		adminGroup.POST("/fixtures", func(c *gin.Context) {
+			handleUploadFixture(c, config)
+		})
+		adminGroup.GET("/fixtures", func(c *gin.Context) {
+			handleListFixtures(c, config)
+		})
+	}
 
 	// API routes
 	apiGroup := router.Group("/v1")
@@ -88,6 +145,16 @@ func setupRouter(config *Config) *gin.Engine {
 			handleEmbeddings(c, config)
 		})
 
+		// Image generation endpoint
+		apiGroup.POST("/images/generations", func(c *gin.Context) {
+			handleImageGenerations(c, config)
+		})
+
+		// Audio transcription endpoint
+		apiGroup.POST("/audio/transcriptions", func(c *gin.Context) {
+			handleAudioTranscriptions(c, config)
+		})
+
 		// Models endpoint
 		apiGroup.GET("/models", func(c *gin.Context) {
 			handleModels(c, config)
@@ -97,8 +164,28 @@ func setupRouter(config *Config) *gin.Engine {
 	return router
 }
 
-func simulateLatencyMiddleware(min, max time.Duration) gin.HandlerFunc {
+// resolveLatencyBounds returns the min/max latency to simulate for model,
+// giving a profile-level latency override precedence over the global
+// config values. Shared by simulateLatencyMiddleware and
+// sleepForConfiguredLatency so per-chunk stream latency honors the same
+// override a non-streaming request would.
+func resolveLatencyBounds(config *Config, model string) (time.Duration, time.Duration) {
+	min, max := config.MinLatency, config.MaxLatency
+
+	if profile, ok := config.Profiles[model]; ok && profile.LatencyMin > 0 {
+		min, max = profile.LatencyMin, profile.LatencyMax
+	}
+
+	return min, max
+}
+
+// simulateLatencyMiddleware sleeps for a random duration before continuing
+// the request. If the request body names a model with a profile-level
+// latency override, that takes precedence over the global config latency.
+func simulateLatencyMiddleware(config *Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		min, max := resolveLatencyBounds(config, peekRequestModel(c))
+
 		if min > 0 {
 			latency := min
 			if max > min {
@@ -111,25 +198,103 @@ func simulateLatencyMiddleware(min, max time.Duration) gin.HandlerFunc {
 	}
 }
 
+// peekedBodyContextKey is the gin context key peekRequestBody caches the
+// request body under, so the fault-injection, latency and recording
+// middlewares and the fixture lookup can all peek at the same request
+// without each re-reading and re-buffering the body.
+const peekedBodyContextKey = "peeked_request_body"
+
+// peekRequestBody reads the request body without consuming it, restoring
+// it so downstream handlers can still bind it normally. The result is
+// cached on the gin context, so only the first peek in a request's
+// middleware chain actually reads the body.
+func peekRequestBody(c *gin.Context) []byte {
+	if cached, ok := c.Get(peekedBodyContextKey); ok {
+		return cached.([]byte)
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		if b, err := io.ReadAll(c.Request.Body); err == nil {
+			body = b
+			c.Request.Body = io.NopCloser(bytes.NewReader(b))
+		}
+	}
+
+	c.Set(peekedBodyContextKey, body)
+	return body
+}
+
+// peekRequestModel reads the "model" field out of the (cached) request
+// body without consuming it.
+func peekRequestModel(c *gin.Context) string {
+	var peek struct {
+		Model string `json:"model"`
+	}
+	_ = json.Unmarshal(peekRequestBody(c), &peek)
+	return peek.Model
+}
+
 // Config represents the server configuration
 type Config struct {
-	Port       int           `json:"port"`
-	MinLatency time.Duration `json:"min_latency"`
-	MaxLatency time.Duration `json:"max_latency"`
-	Models     Models        `json:"models"`
+	Port                        int                     `json:"port"`
+	MinLatency                  time.Duration           `json:"min_latency"`
+	MaxLatency                  time.Duration           `json:"max_latency"`
+	Models                      Models                  `json:"models"`
+	Profiles                    map[string]ModelProfile `json:"profiles"`
+	Faults                      FaultConfig             `json:"faults"`
+	faultsMu                    sync.RWMutex
+	RecordDir                   string `json:"record_dir"`
+	ReplayDir                   string `json:"replay_dir"`
+	fixtures                    map[string]Fixture
+	fixturesMu                  sync.RWMutex
+	TranscriptionMaxUploadBytes int64 `json:"transcription_max_upload_bytes"`
+}
+
+// ModelProfile customizes the mock behavior for a single model ID, letting
+// different models behave differently in ways that matter for integration
+// tests (response content, token counts, embedding size, finish reasons,
+// latency).
+type ModelProfile struct {
+	ResponseTemplate    string             `json:"response_template"`
+	CompletionTokensMin int                `json:"completion_tokens_min"`
+	CompletionTokensMax int                `json:"completion_tokens_max"`
+	EmbeddingDimensions int                `json:"embedding_dimensions"`
+	FinishReasonWeights map[string]float64 `json:"finish_reason_weights"`
+	LatencyMin          time.Duration      `json:"-"`
+	LatencyMax          time.Duration      `json:"-"`
 }
 
 type Models struct {
-	Embedding []string `json:"embedding"`
-	Chat      []string `json:"chat"`
+	Embedding     []string `json:"embedding"`
+	Chat          []string `json:"chat"`
+	Image         []string `json:"image"`
+	Transcription []string `json:"transcription"`
 }
 
 // ConfigFile represents the JSON structure of the config file
 type ConfigFile struct {
-	Port       int    `json:"port"`
-	MinLatency string `json:"min_latency"`
-	MaxLatency string `json:"max_latency"`
-	Models     Models `json:"models"`
+	Port                        int                         `json:"port"`
+	MinLatency                  string                      `json:"min_latency"`
+	MaxLatency                  string                      `json:"max_latency"`
+	Models                      Models                      `json:"models"`
+	Profiles                    map[string]ModelProfileFile `json:"profiles"`
+	Faults                      FaultConfig                 `json:"faults"`
+	RecordDir                   string                      `json:"record_dir"`
+	ReplayDir                   string                      `json:"replay_dir"`
+	TranscriptionMaxUploadBytes int64                       `json:"transcription_max_upload_bytes"`
+}
+
+// ModelProfileFile is the JSON structure of a ModelProfile entry in the
+// config file, where latencies are expressed as duration strings.
+type ModelProfileFile struct {
+	ResponseTemplate    string             `json:"response_template"`
+	CompletionTokensMin int                `json:"completion_tokens_min"`
+	CompletionTokensMax int                `json:"completion_tokens_max"`
+	EmbeddingDimensions int                `json:"embedding_dimensions"`
+	FinishReasonWeights map[string]float64 `json:"finish_reason_weights"`
+	LatencyMin          string             `json:"latency_min"`
+	LatencyMax          string             `json:"latency_max"`
 }
 
 func loadConfig(configPath string) (*Config, error) {
@@ -159,25 +324,114 @@ func loadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("invalid max_latency: %v", err)
 	}
 
+	profiles, err := convertProfiles(configFile.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
-		Port:       configFile.Port,
-		MinLatency: minLatency,
-		MaxLatency: maxLatency,
-		Models:     configFile.Models,
+		Port:                        configFile.Port,
+		MinLatency:                  minLatency,
+		MaxLatency:                  maxLatency,
+		Models:                      configFile.Models,
+		Profiles:                    profiles,
+		Faults:                      configFile.Faults,
+		RecordDir:                   configFile.RecordDir,
+		ReplayDir:                   configFile.ReplayDir,
+		TranscriptionMaxUploadBytes: configFile.TranscriptionMaxUploadBytes,
 	}
 
 	return config, nil
 }
 
+// convertProfiles converts the config file's string-latency profiles into
+// ModelProfile entries with parsed time.Duration values.
+func convertProfiles(profileFiles map[string]ModelProfileFile) (map[string]ModelProfile, error) {
+	if profileFiles == nil {
+		return nil, nil
+	}
+
+	profiles := make(map[string]ModelProfile, len(profileFiles))
+	for model, pf := range profileFiles {
+		profile := ModelProfile{
+			ResponseTemplate:    pf.ResponseTemplate,
+			CompletionTokensMin: pf.CompletionTokensMin,
+			CompletionTokensMax: pf.CompletionTokensMax,
+			EmbeddingDimensions: pf.EmbeddingDimensions,
+			FinishReasonWeights: pf.FinishReasonWeights,
+		}
+
+		if pf.LatencyMin != "" {
+			latencyMin, err := time.ParseDuration(pf.LatencyMin)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency_min for model %q: %v", model, err)
+			}
+			profile.LatencyMin = latencyMin
+		}
+
+		if pf.LatencyMax != "" {
+			latencyMax, err := time.ParseDuration(pf.LatencyMax)
+			if err != nil {
+				return nil, fmt.Errorf("invalid latency_max for model %q: %v", model, err)
+			}
+			profile.LatencyMax = latencyMax
+		}
+
+		profiles[model] = profile
+	}
+
+	return profiles, nil
+}
+
 // ChatCompletionRequest represents the request structure for chat completions
 type ChatCompletionRequest struct {
-	Model    string                  `json:"model"`
-	Messages []ChatCompletionMessage `json:"messages"`
+	Model        string                  `json:"model"`
+	Messages     []ChatCompletionMessage `json:"messages"`
+	Stream       bool                    `json:"stream"`
+	Tools        []Tool                  `json:"tools,omitempty"`
+	ToolChoice   interface{}             `json:"tool_choice,omitempty"`
+	Functions    []FunctionDef           `json:"functions,omitempty"`
+	FunctionCall interface{}             `json:"function_call,omitempty"`
 }
 
 type ChatCompletionMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role         string        `json:"role"`
+	Content      string        `json:"content"`
+	Name         string        `json:"name,omitempty"`
+	ToolCallID   string        `json:"tool_call_id,omitempty"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+}
+
+// Tool represents an entry in the request's `tools` array, the current
+// OpenAI way of describing a callable function.
+type Tool struct {
+	Type     string      `json:"type"`
+	Function FunctionDef `json:"function"`
+}
+
+// FunctionDef describes a callable function's name, description and JSON
+// Schema parameters, shared by both the `tools` and legacy `functions`
+// request fields.
+type FunctionDef struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ToolCall represents a single function invocation the mock "decided" to
+// make, returned in a message's `tool_calls`.
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall carries a function name and its JSON-encoded arguments,
+// used both for legacy `function_call` responses and inside a ToolCall.
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // ChatCompletionResponse represents the response structure for chat completions
@@ -198,6 +452,24 @@ type ChatCompletionResponse struct {
 	} `json:"usage"`
 }
 
+// ChatCompletionChunk represents a single SSE chunk emitted for a streamed
+// chat completion, matching OpenAI's `chat.completion.chunk` object.
+type ChatCompletionChunk struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role      string     `json:"role,omitempty"`
+			Content   string     `json:"content,omitempty"`
+			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
 // EmbeddingRequest represents the request structure for embeddings
 type EmbeddingRequest struct {
 	Model string   `json:"model"`
@@ -231,6 +503,11 @@ type ModelsResponse struct {
 }
 
 func handleChatCompletions(c *gin.Context, config *Config) {
+	if fixture, ok := lookupFixture(config, c, "/v1/chat/completions"); ok {
+		serveFixture(c, fixture)
+		return
+	}
+
 	var req ChatCompletionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// Log warning but continue processing with default values
@@ -264,7 +541,7 @@ func handleChatCompletions(c *gin.Context, config *Config) {
 	}
 
 	// Ensure we have messages to process
-	if req.Messages == nil {
+	if len(req.Messages) == 0 {
 		req.Messages = []ChatCompletionMessage{
 			{
 				Role:    "user",
@@ -273,6 +550,36 @@ func handleChatCompletions(c *gin.Context, config *Config) {
 		}
 	}
 
+	// A "tool" message is the caller reporting back the result of a
+	// function we asked it to invoke; echo it into the next assistant turn
+	// instead of deciding on another tool call.
+	if lastMessage := req.Messages[len(req.Messages)-1]; lastMessage.Role == "tool" {
+		respondWithToolResultEcho(c, req, lastMessage)
+		return
+	}
+
+	if toolCall, ok := decideToolCall(req); ok {
+		if req.Stream {
+			streamToolCall(c, req, toolCall)
+			return
+		}
+		respondWithToolCall(c, req, toolCall)
+		return
+	}
+
+	profile := config.Profiles[req.Model]
+	mockContent := renderMockContent(profile, req)
+
+	if req.Stream {
+		streamChatCompletion(c, req, mockContent, config)
+		return
+	}
+
+	completionTokens := 20
+	if profile.CompletionTokensMax > 0 {
+		completionTokens = sampleTokenCount(profile.CompletionTokensMin, profile.CompletionTokensMax)
+	}
+
 	// Generate a mock response
 	response := ChatCompletionResponse{
 		ID:      fmt.Sprintf("chatcmpl-%s", randomID(29)),
@@ -288,9 +595,9 @@ func handleChatCompletions(c *gin.Context, config *Config) {
 				Index: 0,
 				Message: ChatCompletionMessage{
 					Role:    "assistant",
-					Content: "This is a mock response from the OpenAI API emulator. Your request has been processed successfully.",
+					Content: mockContent,
 				},
-				FinishReason: "stop",
+				FinishReason: pickFinishReason(profile),
 			},
 		},
 		Usage: struct {
@@ -299,15 +606,271 @@ func handleChatCompletions(c *gin.Context, config *Config) {
 			TotalTokens      int `json:"total_tokens"`
 		}{
 			PromptTokens:     calculateTokens(req.Messages),
-			CompletionTokens: 20,
-			TotalTokens:      calculateTokens(req.Messages) + 20,
+			CompletionTokens: completionTokens,
+			TotalTokens:      calculateTokens(req.Messages) + completionTokens,
 		},
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// chatTemplateData is the data made available to a ModelProfile's
+// response_template.
+type chatTemplateData struct {
+	Messages        []ChatCompletionMessage
+	LastUserMessage string
+	Model           string
+	Now             time.Time
+}
+
+// renderMockContent renders the profile's response_template if set,
+// otherwise falls back to the default canned mock response.
+func renderMockContent(profile ModelProfile, req ChatCompletionRequest) string {
+	const defaultContent = "This is a mock response from the OpenAI API emulator. Your request has been processed successfully."
+
+	if profile.ResponseTemplate == "" {
+		return defaultContent
+	}
+
+	tmpl, err := template.New("response").Parse(profile.ResponseTemplate)
+	if err != nil {
+		log.Printf("Warning: Invalid response_template for model %q: %v. Using default content.", req.Model, err)
+		return defaultContent
+	}
+
+	data := chatTemplateData{
+		Messages: req.Messages,
+		Model:    req.Model,
+		Now:      time.Now(),
+	}
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			data.LastUserMessage = req.Messages[i].Content
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("Warning: Failed to execute response_template for model %q: %v. Using default content.", req.Model, err)
+		return defaultContent
+	}
+
+	return buf.String()
+}
+
+// sampleTokenCount picks a random token count in [min, max], used to vary
+// the reported completion token usage per profile.
+func sampleTokenCount(min, max int) int {
+	if max <= min {
+		return max
+	}
+	return min + rand.Intn(max-min+1)
+}
+
+// pickFinishReason samples a finish reason according to the profile's
+// finish_reason_weights, falling back to "stop" when unset.
+func pickFinishReason(profile ModelProfile) string {
+	if len(profile.FinishReasonWeights) == 0 {
+		return "stop"
+	}
+
+	total := 0.0
+	for _, weight := range profile.FinishReasonWeights {
+		total += weight
+	}
+	if total <= 0 {
+		return "stop"
+	}
+
+	pick := rand.Float64() * total
+	cumulative := 0.0
+	for reason, weight := range profile.FinishReasonWeights {
+		cumulative += weight
+		if pick < cumulative {
+			return reason
+		}
+	}
+
+	return "stop"
+}
+
+// streamChatCompletion emits mockContent as a series of `chat.completion.chunk`
+// SSE events, honoring the configured latency between chunks, then a final
+// `data: [DONE]` sentinel, following the OpenAI streaming protocol.
+func streamChatCompletion(c *gin.Context, req ChatCompletionRequest, mockContent string, config *Config) {
+	id := fmt.Sprintf("chatcmpl-%s", randomID(29))
+	created := time.Now().Unix()
+	words := strings.Split(mockContent, " ")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	writeChunk := func(content string, finishReason *string) {
+		chunk := ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.Model,
+		}
+		chunk.Choices = []struct {
+			Index int `json:"index"`
+			Delta struct {
+				Role      string     `json:"role,omitempty"`
+				Content   string     `json:"content,omitempty"`
+				ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+			} `json:"delta"`
+			FinishReason *string `json:"finish_reason"`
+		}{
+			{Index: 0, FinishReason: finishReason},
+		}
+		chunk.Choices[0].Delta.Content = content
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+	}
+
+	// First chunk carries the role, matching OpenAI's streaming behavior.
+	roleChunk := ChatCompletionChunk{
+		ID:      id,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   req.Model,
+	}
+	roleChunk.Choices = []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role      string     `json:"role,omitempty"`
+			Content   string     `json:"content,omitempty"`
+			ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	}{
+		{Index: 0},
+	}
+	roleChunk.Choices[0].Delta.Role = "assistant"
+	data, _ := json.Marshal(roleChunk)
+	fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+	c.Writer.Flush()
+
+	partialStream, _ := c.Get(faultPartialStreamKey)
+	cutoff := len(words)
+	if dropMidStream, ok := partialStream.(bool); ok && dropMidStream {
+		cutoff = len(words) / 2
+	}
+
+	for i, word := range words {
+		if i >= cutoff {
+			dropStreamConnection(c)
+			return
+		}
+
+		sleepForConfiguredLatency(config, req.Model)
+		content := word
+		if i < len(words)-1 {
+			content += " "
+		}
+		writeChunk(content, nil)
+	}
+
+	stopReason := "stop"
+	writeChunk("", &stopReason)
+
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// streamToolCall emits a decided tool call as a `chat.completion.chunk`
+// SSE sequence: a role chunk, a chunk carrying the full tool call in one
+// delta, then a finish_reason "tool_calls" chunk and `[DONE]`, mirroring
+// OpenAI's streamed tool-calling shape closely enough for clients that
+// only care about the final assembled call rather than incremental
+// argument deltas.
+func streamToolCall(c *gin.Context, req ChatCompletionRequest, toolCall ToolCall) {
+	id := fmt.Sprintf("chatcmpl-%s", randomID(29))
+	created := time.Now().Unix()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	writeChunk := func(role string, toolCalls []ToolCall, finishReason *string) {
+		chunk := ChatCompletionChunk{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   req.Model,
+		}
+		chunk.Choices = []struct {
+			Index int `json:"index"`
+			Delta struct {
+				Role      string     `json:"role,omitempty"`
+				Content   string     `json:"content,omitempty"`
+				ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+			} `json:"delta"`
+			FinishReason *string `json:"finish_reason"`
+		}{
+			{Index: 0, FinishReason: finishReason},
+		}
+		chunk.Choices[0].Delta.Role = role
+		chunk.Choices[0].Delta.ToolCalls = toolCalls
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		c.Writer.Flush()
+	}
+
+	writeChunk("assistant", nil, nil)
+
+	partialStream, _ := c.Get(faultPartialStreamKey)
+	if dropMidStream, ok := partialStream.(bool); ok && dropMidStream {
+		dropStreamConnection(c)
+		return
+	}
+
+	writeChunk("", []ToolCall{toolCall}, nil)
+
+	finishReason := "tool_calls"
+	writeChunk("", nil, &finishReason)
+
+	fmt.Fprint(c.Writer, "data: [DONE]\n\n")
+	c.Writer.Flush()
+}
+
+// dropStreamConnection hijacks and closes the connection without writing
+// anything further, simulating a backend that died mid-response. Shared by
+// streamChatCompletion and streamToolCall so both honor the
+// faultPartialStreamKey fault the same way.
+func dropStreamConnection(c *gin.Context) {
+	if hijacker, ok := c.Writer.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			conn.Close()
+		}
+	}
+}
+
+// sleepForConfiguredLatency sleeps for a random duration between the
+// resolved min and max latency for model (profile override or global
+// config, via resolveLatencyBounds), for use between individual stream
+// chunks.
+func sleepForConfiguredLatency(config *Config, model string) {
+	min, max := resolveLatencyBounds(config, model)
+	if min <= 0 {
+		return
+	}
+	latency := min
+	if max > min {
+		latency = min + time.Duration(float64(max-min)*rand.Float64())
+	}
+	time.Sleep(latency)
+}
+
 func handleEmbeddings(c *gin.Context, config *Config) {
+	if fixture, ok := lookupFixture(config, c, "/v1/embeddings"); ok {
+		serveFixture(c, fixture)
+		return
+	}
+
 	var req EmbeddingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		// Log warning but continue processing with default values
@@ -363,10 +926,15 @@ func handleEmbeddings(c *gin.Context, config *Config) {
 		},
 	}
 
+	dimensions := 1536 // OpenAI embeddings are typically 1536 dimensions
+	if profile, ok := config.Profiles[req.Model]; ok && profile.EmbeddingDimensions > 0 {
+		dimensions = profile.EmbeddingDimensions
+	}
+
 	// Generate mock embeddings for each input
 	for i, input := range req.Input {
 		// Create a deterministic but random-looking embedding vector
-		embedding := generateMockEmbedding(input, 1536) // OpenAI embeddings are typically 1536 dimensions
+		embedding := generateMockEmbedding(input, dimensions)
 
 		response.Data[i] = struct {
 			Object    string    `json:"object"`
@@ -388,6 +956,11 @@ func handleEmbeddings(c *gin.Context, config *Config) {
 }
 
 func handleModels(c *gin.Context, config *Config) {
+	if fixture, ok := lookupFixture(config, c, "/v1/models"); ok {
+		serveFixture(c, fixture)
+		return
+	}
+
 	response := ModelsResponse{
 		Object: "list",
 		Data: []struct {
@@ -428,6 +1001,36 @@ func handleModels(c *gin.Context, config *Config) {
 		})
 	}
 
+	// Add image models
+	for _, model := range config.Models.Image {
+		response.Data = append(response.Data, struct {
+			ID      string `json:"id"`
+			Object  string `json:"object"`
+			Created int64  `json:"created"`
+			OwnedBy string `json:"owned_by"`
+		}{
+			ID:      model,
+			Object:  "model",
+			Created: time.Now().Unix() - 86400*30, // 30 days ago
+			OwnedBy: "openai",
+		})
+	}
+
+	// Add transcription models
+	for _, model := range config.Models.Transcription {
+		response.Data = append(response.Data, struct {
+			ID      string `json:"id"`
+			Object  string `json:"object"`
+			Created int64  `json:"created"`
+			OwnedBy string `json:"owned_by"`
+		}{
+			ID:      model,
+			Object:  "model",
+			Created: time.Now().Unix() - 86400*30, // 30 days ago
+			OwnedBy: "openai",
+		})
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestHandleModelsIncludesAllModelKinds guards against a regression where
+// /v1/models only listed chat and embedding models, omitting image and
+// transcription models from the response.
+func TestHandleModelsIncludesAllModelKinds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config := &Config{Models: Models{
+		Chat:          []string{"gpt-4"},
+		Embedding:     []string{"text-embedding-ada-002"},
+		Image:         []string{"dall-e-3"},
+		Transcription: []string{"whisper-1"},
+	}}
+
+	router := gin.New()
+	router.GET("/v1/models", func(c *gin.Context) {
+		handleModels(c, config)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var response ModelsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	ids := make(map[string]bool)
+	for _, model := range response.Data {
+		ids[model.ID] = true
+	}
+
+	for _, want := range []string{"gpt-4", "text-embedding-ada-002", "dall-e-3", "whisper-1"} {
+		if !ids[want] {
+			t.Errorf("response missing model %q: %v", want, ids)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTranscriptionMaxUploadBytes bounds how much of an uploaded audio
+// file handleAudioTranscriptions will read before discarding the rest, when
+// Config.TranscriptionMaxUploadBytes isn't set.
+const defaultTranscriptionMaxUploadBytes = 25 << 20 // 25MB, matching OpenAI's limit
+
+// maxImageGenerationN caps the caller-supplied `n` on image generation
+// requests, matching OpenAI's own limit, so a single request can't force an
+// unbounded allocation or loop.
+const maxImageGenerationN = 10
+
+// ImageGenerationRequest represents the request structure for image generation
+type ImageGenerationRequest struct {
+	Model          string `json:"model"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// ImageGenerationResponse represents the response structure for image generation
+type ImageGenerationResponse struct {
+	Created int64 `json:"created"`
+	Data    []struct {
+		URL     string `json:"url,omitempty"`
+		B64JSON string `json:"b64_json,omitempty"`
+	} `json:"data"`
+}
+
+// TranscriptionResponse represents the response structure for audio transcriptions
+type TranscriptionResponse struct {
+	Text string `json:"text"`
+}
+
+func handleImageGenerations(c *gin.Context, config *Config) {
+	var req ImageGenerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Printf("Warning: Malformed image generation request: %v. Continuing with default values.", err)
+	}
+
+	modelSupported := false
+	for _, model := range config.Models.Image {
+		if model == req.Model {
+			modelSupported = true
+			break
+		}
+	}
+
+	if !modelSupported && req.Model != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"message": fmt.Sprintf("The model '%s' does not exist", req.Model),
+				"type":    "invalid_request_error",
+				"param":   "model",
+				"code":    "model_not_found",
+			},
+		})
+		return
+	}
+
+	if req.Model == "" && len(config.Models.Image) > 0 {
+		req.Model = config.Models.Image[0]
+	}
+
+	if req.N <= 0 {
+		req.N = 1
+	}
+
+	if req.N > maxImageGenerationN {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"message": fmt.Sprintf("The maximum value for `n` is %d.", maxImageGenerationN),
+				"type":    "invalid_request_error",
+				"param":   "n",
+				"code":    "invalid_value",
+			},
+		})
+		return
+	}
+
+	response := ImageGenerationResponse{
+		Created: time.Now().Unix(),
+		Data: make([]struct {
+			URL     string `json:"url,omitempty"`
+			B64JSON string `json:"b64_json,omitempty"`
+		}, req.N),
+	}
+
+	for i := 0; i < req.N; i++ {
+		if req.ResponseFormat == "b64_json" {
+			response.Data[i].B64JSON = generateMockImageB64(req.Prompt, i)
+		} else {
+			response.Data[i].URL = fmt.Sprintf("https://mock.local/img/%s.png", randomID(20))
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func handleAudioTranscriptions(c *gin.Context, config *Config) {
+	model := c.PostForm("model")
+
+	modelSupported := false
+	for _, m := range config.Models.Transcription {
+		if m == model {
+			modelSupported = true
+			break
+		}
+	}
+
+	if !modelSupported && model != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"message": fmt.Sprintf("The model '%s' does not exist", model),
+				"type":    "invalid_request_error",
+				"param":   "model",
+				"code":    "model_not_found",
+			},
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		log.Printf("Warning: Malformed audio transcription request: %v. Continuing with default values.", err)
+		c.JSON(http.StatusOK, TranscriptionResponse{Text: "This is a mock transcription of your audio."})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusOK, TranscriptionResponse{Text: "This is a mock transcription of your audio."})
+		return
+	}
+	defer file.Close()
+
+	// Read (and discard) the upload so the mock behaves like a real
+	// transcription backend without actually processing any audio.
+	limit := int64(defaultTranscriptionMaxUploadBytes)
+	if config.TranscriptionMaxUploadBytes > 0 {
+		limit = config.TranscriptionMaxUploadBytes
+	}
+	n, _ := io.CopyN(io.Discard, file, limit)
+
+	c.JSON(http.StatusOK, TranscriptionResponse{
+		Text: generateMockTranscription(fileHeader.Filename, n),
+	})
+}
+
+// generateMockImageB64 renders a tiny deterministic PNG seeded from the
+// prompt and index so repeated requests with the same prompt return the
+// same image.
+func generateMockImageB64(prompt string, index int) string {
+	seed := int64(index)
+	for _, c := range prompt {
+		seed = seed*31 + int64(c)
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.RGBA{
+		R: uint8(r.Intn(256)),
+		G: uint8(r.Intn(256)),
+		B: uint8(r.Intn(256)),
+		A: 255,
+	})
+
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// generateMockTranscription derives deterministic mock transcript text
+// from the uploaded file's name and size.
+func generateMockTranscription(filename string, size int64) string {
+	return fmt.Sprintf("This is a mock transcription of '%s' (%d bytes read).", filename, size)
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fixture is the on-disk (and in-memory) record of a single captured
+// request/response pair, keyed by fixtureKey. It's the unit both recording
+// and replay operate on, and what /admin/fixtures uploads and lists.
+// Response is a raw byte slice rather than json.RawMessage: streamed chat
+// completions record as SSE text, not JSON, and encoding/json base64-encodes
+// a []byte automatically, so both shapes round-trip unchanged.
+type Fixture struct {
+	Request  FixtureRequest `json:"request"`
+	Response []byte         `json:"response"`
+	Status   int            `json:"status"`
+	Headers  http.Header    `json:"headers"`
+}
+
+// FixtureRequest identifies the request a Fixture was captured for. Path and
+// Model are the inputs to fixtureKey; Body is kept for debugging and for
+// recomputing the key when a fixture file is loaded from disk.
+type FixtureRequest struct {
+	Path  string          `json:"path"`
+	Model string          `json:"model"`
+	Body  json.RawMessage `json:"body"`
+}
+
+// fixtureKey derives a stable lookup key for a request from its path, model
+// and canonicalized body, so that requests differing only in JSON key order
+// or whitespace still hit the same fixture.
+func fixtureKey(path, model string, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", path, model)
+	h.Write(canonicalizeJSON(body))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalizeJSON re-marshals body so that semantically identical JSON
+// documents produce identical bytes (encoding/json sorts object keys on
+// marshal). Bodies that aren't valid JSON (or are empty, as for GET
+// requests) are hashed as-is.
+func canonicalizeJSON(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return canonical
+}
+
+// lookupFixture checks whether a fixture matching this request's path,
+// model and body is loaded, returning it if so. Callers serve it with
+// serveFixture and skip synthesizing a response.
+func lookupFixture(config *Config, c *gin.Context, path string) (Fixture, bool) {
+	config.fixturesMu.RLock()
+	defer config.fixturesMu.RUnlock()
+
+	if len(config.fixtures) == 0 {
+		return Fixture{}, false
+	}
+
+	body := peekRequestBody(c)
+	fixture, ok := config.fixtures[fixtureKey(path, peekRequestModel(c), body)]
+	return fixture, ok
+}
+
+// serveFixture replays a fixture's captured status, headers and body
+// byte-for-byte, including whatever `created` timestamp it was captured
+// with.
+func serveFixture(c *gin.Context, fixture Fixture) {
+	for key, values := range fixture.Headers {
+		for _, value := range values {
+			c.Writer.Header().Add(key, value)
+		}
+	}
+
+	status := fixture.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	c.Writer.WriteHeader(status)
+	c.Writer.Write(fixture.Response)
+}
+
+// recordingWriter wraps gin's ResponseWriter to capture the status and body
+// a handler writes, so recordingMiddleware can persist them as a fixture
+// after the handler returns. Modeled on faults.go's truncatingWriter.
+type recordingWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// recordingMiddleware captures every request/response pair to
+// config.RecordDir as a fixture when record mode is enabled; it's a no-op
+// otherwise.
+func recordingMiddleware(config *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.RecordDir == "" {
+			c.Next()
+			return
+		}
+
+		body := peekRequestBody(c)
+		model := peekRequestModel(c)
+
+		rw := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = rw
+		c.Next()
+
+		fixture := Fixture{
+			Request: FixtureRequest{
+				Path:  c.Request.URL.Path,
+				Model: model,
+				Body:  body,
+			},
+			Response: rw.body.Bytes(),
+			Status:   rw.status,
+			Headers:  rw.Header().Clone(),
+		}
+
+		if err := writeFixture(config.RecordDir, fixture); err != nil {
+			log.Printf("Warning: failed to record fixture for %s: %v", c.Request.URL.Path, err)
+		}
+	}
+}
+
+// writeFixture persists a fixture to <dir>/<key>.json, where key is derived
+// from the fixture's request so a later replay run can find it by path,
+// model and body alone.
+func writeFixture(dir string, fixture Fixture) error {
+	key := fixtureKey(fixture.Request.Path, fixture.Request.Model, fixture.Request.Body)
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, key+".json"), data, 0o644)
+}
+
+// loadFixtures reads every *.json file in dir into a map keyed by the hash
+// recomputed from each fixture's captured request, so replay lookups don't
+// depend on filenames surviving a copy or rename.
+func loadFixtures(dir string) (map[string]Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make(map[string]Fixture)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading fixture %q: %v", path, err)
+		}
+
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("parsing fixture %q: %v", path, err)
+		}
+
+		key := fixtureKey(fixture.Request.Path, fixture.Request.Model, fixture.Request.Body)
+		fixtures[key] = fixture
+	}
+
+	return fixtures, nil
+}
+
+// handleUploadFixture stores a fixture uploaded at runtime, making it
+// available for replay immediately without restarting the server.
+func handleUploadFixture(c *gin.Context, config *Config) {
+	var fixture Fixture
+	if err := c.ShouldBindJSON(&fixture); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": map[string]interface{}{
+				"message": fmt.Sprintf("Invalid fixture: %v", err),
+				"type":    "invalid_request_error",
+			},
+		})
+		return
+	}
+
+	key := fixtureKey(fixture.Request.Path, fixture.Request.Model, fixture.Request.Body)
+
+	config.fixturesMu.Lock()
+	if config.fixtures == nil {
+		config.fixtures = make(map[string]Fixture)
+	}
+	config.fixtures[key] = fixture
+	config.fixturesMu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"key": key})
+}
+
+// fixtureSummary is the listing shape returned by GET /admin/fixtures; it
+// omits the captured body/response so the listing stays small even with
+// many large fixtures loaded.
+type fixtureSummary struct {
+	Key    string `json:"key"`
+	Path   string `json:"path"`
+	Model  string `json:"model"`
+	Status int    `json:"status"`
+}
+
+// handleListFixtures lists the fixtures currently loaded for replay.
+func handleListFixtures(c *gin.Context, config *Config) {
+	config.fixturesMu.RLock()
+	defer config.fixturesMu.RUnlock()
+
+	summaries := make([]fixtureSummary, 0, len(config.fixtures))
+	for key, fixture := range config.fixtures {
+		summaries = append(summaries, fixtureSummary{
+			Key:    key,
+			Path:   fixture.Request.Path,
+			Model:  fixture.Request.Model,
+			Status: fixture.Status,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"fixtures": summaries})
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestFixtureKeyStableAcrossKeyOrderAndWhitespace(t *testing.T) {
+	a := fixtureKey("/v1/chat/completions", "gpt-4", []byte(`{"model":"gpt-4","messages":[{"role":"user","content":"hi"}]}`))
+	b := fixtureKey("/v1/chat/completions", "gpt-4", []byte(`{"messages": [{"content": "hi", "role": "user"}], "model": "gpt-4"}`))
+
+	if a != b {
+		t.Errorf("fixtureKey differs for semantically identical bodies: %q vs %q", a, b)
+	}
+}
+
+func TestFixtureKeyDiffersByPathModelOrBody(t *testing.T) {
+	base := fixtureKey("/v1/chat/completions", "gpt-4", []byte(`{"model":"gpt-4"}`))
+
+	cases := map[string]string{
+		"different path":  fixtureKey("/v1/embeddings", "gpt-4", []byte(`{"model":"gpt-4"}`)),
+		"different model": fixtureKey("/v1/chat/completions", "gpt-3.5-turbo", []byte(`{"model":"gpt-4"}`)),
+		"different body":  fixtureKey("/v1/chat/completions", "gpt-4", []byte(`{"model":"gpt-4","extra":true}`)),
+	}
+
+	for name, key := range cases {
+		if key == base {
+			t.Errorf("%s: fixtureKey unexpectedly matches base", name)
+		}
+	}
+}
+
+func TestCanonicalizeJSONNonJSONBody(t *testing.T) {
+	body := []byte("not json")
+	if got := canonicalizeJSON(body); string(got) != string(body) {
+		t.Errorf("canonicalizeJSON(%q) = %q, want unchanged", body, got)
+	}
+}
+
+func TestCanonicalizeJSONEmptyBody(t *testing.T) {
+	if got := canonicalizeJSON(nil); got != nil {
+		t.Errorf("canonicalizeJSON(nil) = %q, want nil", got)
+	}
+}
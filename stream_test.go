@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hijackableRecorder adapts httptest.NewRecorder to satisfy http.Hijacker,
+// since the real net/http.ResponseWriter used in production supports
+// hijacking but httptest's recorder does not.
+type hijackableRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (h *hijackableRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	server, client := net.Pipe()
+	client.Close()
+	return server, bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)), nil
+}
+
+// TestStreamToolCallHonorsPartialStreamFault guards against a regression
+// where the partial_stream fault dropped streamed chat completions but was
+// silently ignored for streamed tool calls.
+func TestStreamToolCallHonorsPartialStreamFault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rec := &hijackableRecorder{httptest.NewRecorder()}
+	c, _ := gin.CreateTestContext(rec)
+	c.Writer = &ginResponseWriterAdapter{c.Writer, rec}
+	c.Request = httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{}`))
+	c.Set(faultPartialStreamKey, true)
+
+	toolCall := ToolCall{ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather", Arguments: "{}"}}
+	streamToolCall(c, ChatCompletionRequest{Model: "gpt-4"}, toolCall)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `"role":"assistant"`) {
+		t.Fatalf("expected the role chunk to have been written before the drop, got %q", body)
+	}
+	if strings.Contains(body, "tool_calls") || strings.Contains(body, "[DONE]") {
+		t.Errorf("expected streamToolCall to drop the connection before the tool-call chunk, got %q", body)
+	}
+}
+
+// ginResponseWriterAdapter forwards gin.ResponseWriter behavior while
+// delegating Hijack to the underlying hijackableRecorder, since gin's own
+// responseWriter.Hijack unwraps http.ResponseWriter rather than itself.
+type ginResponseWriterAdapter struct {
+	gin.ResponseWriter
+	hijacker http.Hijacker
+}
+
+func (a *ginResponseWriterAdapter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return a.hijacker.Hijack()
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// decideToolCall applies a deterministic policy to decide whether the mock
+// should respond with a tool/function call: an explicit tool_choice or
+// function_call forces (or forbids) a specific function; "auto" falls back
+// to a keyword match between the last user message and each candidate
+// function's name/description.
+func decideToolCall(req ChatCompletionRequest) (ToolCall, bool) {
+	candidates := candidateFunctions(req)
+	if len(candidates) == 0 {
+		return ToolCall{}, false
+	}
+
+	if forced, ok := forcedFunctionName(req); ok {
+		if forced == "none" {
+			return ToolCall{}, false
+		}
+		for _, fn := range candidates {
+			if fn.Name == forced {
+				return buildToolCall(fn, req), true
+			}
+		}
+		// Named function isn't among the candidates; fall through to auto.
+	}
+
+	if requiredToolChoice(req) {
+		return buildToolCall(candidates[0], req), true
+	}
+
+	lastUserMessage := lastMessageByRole(req.Messages, "user")
+	if fn, ok := matchFunctionByKeyword(candidates, lastUserMessage); ok {
+		return buildToolCall(fn, req), true
+	}
+
+	return ToolCall{}, false
+}
+
+// candidateFunctions normalizes the request's `tools` and legacy
+// `functions` fields into a single list of FunctionDefs.
+func candidateFunctions(req ChatCompletionRequest) []FunctionDef {
+	if len(req.Tools) > 0 {
+		fns := make([]FunctionDef, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			fns = append(fns, tool.Function)
+		}
+		return fns
+	}
+	return req.Functions
+}
+
+// forcedFunctionName extracts an explicit function name (or "none") from
+// tool_choice/function_call, whether expressed as a bare string or as the
+// `{"type":"function","function":{"name":"..."}}` object form.
+func forcedFunctionName(req ChatCompletionRequest) (string, bool) {
+	for _, choice := range []interface{}{req.ToolChoice, req.FunctionCall} {
+		switch v := choice.(type) {
+		case string:
+			if v == "none" {
+				return "none", true
+			}
+		case map[string]interface{}:
+			if name, ok := v["name"].(string); ok {
+				return name, true
+			}
+			if fn, ok := v["function"].(map[string]interface{}); ok {
+				if name, ok := fn["name"].(string); ok {
+					return name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// requiredToolChoice reports whether tool_choice/function_call demands
+// that some function always be called.
+func requiredToolChoice(req ChatCompletionRequest) bool {
+	for _, choice := range []interface{}{req.ToolChoice, req.FunctionCall} {
+		if s, ok := choice.(string); ok && s == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFunctionByKeyword picks the first candidate whose name appears in
+// the last user message, or whose description has a word longer than
+// three letters that appears in it (case-insensitive substring match).
+func matchFunctionByKeyword(candidates []FunctionDef, message string) (FunctionDef, bool) {
+	message = strings.ToLower(message)
+	if message == "" {
+		return FunctionDef{}, false
+	}
+
+	for _, fn := range candidates {
+		if strings.Contains(message, strings.ToLower(fn.Name)) {
+			return fn, true
+		}
+		for _, word := range strings.Fields(strings.ToLower(fn.Description)) {
+			if len(word) > 3 && strings.Contains(message, word) {
+				return fn, true
+			}
+		}
+	}
+
+	return FunctionDef{}, false
+}
+
+// lastMessageByRole returns the content of the most recent message with
+// the given role, or "" if none match.
+func lastMessageByRole(messages []ChatCompletionMessage, role string) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == role {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// buildToolCall constructs a ToolCall for fn, filling its arguments from
+// fn.Parameters' JSON Schema.
+func buildToolCall(fn FunctionDef, req ChatCompletionRequest) ToolCall {
+	args := buildMockArguments(fn.Parameters, lastMessageByRole(req.Messages, "user"))
+	return ToolCall{
+		ID:   fmt.Sprintf("call_%s", randomID(24)),
+		Type: "function",
+		Function: FunctionCall{
+			Name:      fn.Name,
+			Arguments: args,
+		},
+	}
+}
+
+// buildMockArguments walks a JSON Schema `parameters` object and fills
+// each property with a type-appropriate mock value: enums pick their
+// first option, strings borrow from the prompt, numbers are zero, and
+// nested objects/arrays get empty mock defaults.
+func buildMockArguments(parameters map[string]interface{}, prompt string) string {
+	args := map[string]interface{}{}
+
+	properties, _ := parameters["properties"].(map[string]interface{})
+	for name, rawSchema := range properties {
+		schema, _ := rawSchema.(map[string]interface{})
+		args[name] = mockValueForSchema(schema, prompt)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+// mockValueForSchema returns a type-appropriate mock value for a single
+// JSON Schema property.
+func mockValueForSchema(schema map[string]interface{}, prompt string) interface{} {
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	switch schema["type"] {
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "array":
+		return []interface{}{}
+	case "object":
+		return map[string]interface{}{}
+	default: // "string" and anything unspecified
+		if prompt != "" {
+			return prompt
+		}
+		return "mock value"
+	}
+}
+
+// respondWithToolCall writes a chat completion response whose only choice
+// is the assistant deciding to invoke toolCall, with finish_reason
+// "tool_calls".
+func respondWithToolCall(c *gin.Context, req ChatCompletionRequest, toolCall ToolCall) {
+	response := ChatCompletionResponse{
+		ID:      fmt.Sprintf("chatcmpl-%s", randomID(29)),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []struct {
+			Index        int                   `json:"index"`
+			Message      ChatCompletionMessage `json:"message"`
+			FinishReason string                `json:"finish_reason"`
+		}{
+			{
+				Index: 0,
+				Message: ChatCompletionMessage{
+					Role:      "assistant",
+					ToolCalls: []ToolCall{toolCall},
+				},
+				FinishReason: "tool_calls",
+			},
+		},
+		Usage: struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		}{
+			PromptTokens:     calculateTokens(req.Messages),
+			CompletionTokens: 20,
+			TotalTokens:      calculateTokens(req.Messages) + 20,
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondWithToolResultEcho replies to a `role: "tool"` message by
+// echoing its content back, simulating the model acknowledging a
+// function's result.
+func respondWithToolResultEcho(c *gin.Context, req ChatCompletionRequest, toolResult ChatCompletionMessage) {
+	content := fmt.Sprintf("Got it — the tool returned: %s", toolResult.Content)
+
+	response := ChatCompletionResponse{
+		ID:      fmt.Sprintf("chatcmpl-%s", randomID(29)),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []struct {
+			Index        int                   `json:"index"`
+			Message      ChatCompletionMessage `json:"message"`
+			FinishReason string                `json:"finish_reason"`
+		}{
+			{
+				Index: 0,
+				Message: ChatCompletionMessage{
+					Role:    "assistant",
+					Content: content,
+				},
+				FinishReason: "stop",
+			},
+		},
+		Usage: struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		}{
+			PromptTokens:     calculateTokens(req.Messages),
+			CompletionTokens: 20,
+			TotalTokens:      calculateTokens(req.Messages) + 20,
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
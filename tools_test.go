@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBuildMockArguments(t *testing.T) {
+	tests := []struct {
+		name       string
+		parameters map[string]interface{}
+		prompt     string
+		want       map[string]interface{}
+	}{
+		{
+			name: "enum picks first option",
+			parameters: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"unit": map[string]interface{}{
+						"type": "string",
+						"enum": []interface{}{"celsius", "fahrenheit"},
+					},
+				},
+			},
+			want: map[string]interface{}{"unit": "celsius"},
+		},
+		{
+			name: "string borrows from prompt",
+			parameters: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+				},
+			},
+			prompt: "Boston",
+			want:   map[string]interface{}{"city": "Boston"},
+		},
+		{
+			name: "string falls back when prompt is empty",
+			parameters: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+				},
+			},
+			want: map[string]interface{}{"city": "mock value"},
+		},
+		{
+			name: "number defaults to zero",
+			parameters: map[string]interface{}{
+				"properties": map[string]interface{}{
+					"count": map[string]interface{}{"type": "integer"},
+				},
+			},
+			want: map[string]interface{}{"count": float64(0)},
+		},
+		{
+			name:       "no properties yields empty object",
+			parameters: map[string]interface{}{},
+			want:       map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildMockArguments(tt.parameters, tt.prompt)
+
+			var parsed map[string]interface{}
+			if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+				t.Fatalf("buildMockArguments returned invalid JSON: %v", err)
+			}
+
+			if len(parsed) != len(tt.want) {
+				t.Fatalf("got %d properties, want %d: %v", len(parsed), len(tt.want), parsed)
+			}
+			for key, wantVal := range tt.want {
+				if parsed[key] != wantVal {
+					t.Errorf("property %q = %v, want %v", key, parsed[key], wantVal)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleChatCompletionsEmptyMessages guards against a regression where
+// an explicit `{"messages": []}` body (a non-nil, zero-length slice once
+// decoded) skipped the default-filling and panicked indexing the last
+// message.
+func TestHandleChatCompletionsEmptyMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	config := &Config{Models: Models{Chat: []string{"gpt-4"}}}
+	router.POST("/v1/chat/completions", func(c *gin.Context) {
+		handleChatCompletions(c, config)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", bytes.NewBufferString(`{"messages":[]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}